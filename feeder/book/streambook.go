@@ -0,0 +1,172 @@
+// Package book maintains a local L2 order book per (exchange, symbol) and
+// decides when the top of book has actually moved, mirroring bbgo's
+// StreamBook but scoped to what the feeder needs: snapshot+diff merging,
+// sequence-gap detection, and publishing to shm.Matrix only on real change.
+package book
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/AlephTX/aleph-tx/feeder/shm"
+)
+
+// PriceLevel is a single price/size pair from a raw exchange update.
+type PriceLevel struct {
+	Price float64
+	Size  float64
+}
+
+// BookUpdate is the common shape every exchange adapter translates its raw
+// messages into before handing them to a StreamBook. A Snapshot update
+// replaces a side wholesale; a diff update merges levels into the existing
+// side, where a zero Size removes that price level.
+type BookUpdate struct {
+	Snapshot bool
+	Bids     []PriceLevel
+	Asks     []PriceLevel
+	Ts       uint64
+
+	// Seq is the exchange's own sequence/offset for this update, if it
+	// provides one. Zero means the feed doesn't sequence updates and gap
+	// detection is skipped for it.
+	Seq uint64
+}
+
+// ErrSequenceGap is returned by Apply when upd.Seq shows one or more diffs
+// were missed since the last applied update. Apply clears the book's side
+// maps before returning it, so a caller that ignores the error still isn't
+// left comparing future diffs against stale state — but the book then has
+// no top of book at all until a fresh snapshot arrives, so the caller
+// should treat this as fatal for the current connection and force a
+// reconnect/resubscribe rather than keep reading.
+var ErrSequenceGap = errors.New("book: sequence gap detected")
+
+// StreamBook maintains the full local L2 book for one (exchange, symbol)
+// pair, analogous to bbgo's NewStreamBook(symbol, exchangeName). Adapters
+// feed it BookUpdate values; it takes care of snapshot/diff merging, gap
+// detection, and deciding when the top of book actually changed.
+type StreamBook struct {
+	Symbol       string
+	ExchangeName string
+
+	mu   sync.Mutex
+	bids map[float64]float64
+	asks map[float64]float64
+
+	haveSeq bool
+	lastSeq uint64
+
+	haveTop      bool
+	bidPx, bidSz float64
+	askPx, askSz float64
+
+	matrix     *shm.Matrix
+	exchangeID uint8
+	symbolID   uint16
+	bound      bool
+}
+
+// NewStreamBook creates an empty book for the given symbol/exchange pair.
+func NewStreamBook(symbol, exchangeName string) *StreamBook {
+	return &StreamBook{
+		Symbol:       symbol,
+		ExchangeName: exchangeName,
+		bids:         make(map[float64]float64),
+		asks:         make(map[float64]float64),
+	}
+}
+
+// BindStream wires the book to a (exchange, symbol) slot in the shared
+// matrix so that every Apply call which changes the top of book republishes
+// a BBO update. Without binding, Apply still maintains book state and can
+// be queried via BestBidAsk, but nothing is written to shared memory.
+func (b *StreamBook) BindStream(matrix *shm.Matrix, exchangeID uint8, symbolID uint16) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.matrix = matrix
+	b.exchangeID = exchangeID
+	b.symbolID = symbolID
+	b.bound = true
+}
+
+// Apply merges a snapshot or diff update into the local book. It returns
+// ErrSequenceGap if upd.Seq shows a missed diff (only checked when the feed
+// supplies sequence numbers). Otherwise it writes to the bound matrix only
+// when the best bid or ask actually moved, which is what keeps
+// SymbolVersions from being bumped on every redundant depth message.
+func (b *StreamBook) Apply(upd BookUpdate) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if upd.Seq != 0 {
+		if !upd.Snapshot && b.haveSeq && upd.Seq != b.lastSeq+1 {
+			// Drop everything we know rather than keep comparing future
+			// diffs against a book with a hole in it. haveSeq is cleared
+			// too, so the next update is accepted unconditionally (it's
+			// expected to be the fresh snapshot a reconnect triggers).
+			b.bids = make(map[float64]float64)
+			b.asks = make(map[float64]float64)
+			b.haveSeq = false
+			b.haveTop = false
+			return ErrSequenceGap
+		}
+		b.haveSeq = true
+		b.lastSeq = upd.Seq
+	}
+
+	if upd.Snapshot {
+		b.bids = make(map[float64]float64, len(upd.Bids))
+		b.asks = make(map[float64]float64, len(upd.Asks))
+	}
+	applySide(b.bids, upd.Bids)
+	applySide(b.asks, upd.Asks)
+
+	bidPx, bidSz, haveBid := bestOf(b.bids, true)
+	askPx, askSz, haveAsk := bestOf(b.asks, false)
+	if !haveBid || !haveAsk {
+		return nil
+	}
+
+	changed := !b.haveTop || bidPx != b.bidPx || bidSz != b.bidSz || askPx != b.askPx || askSz != b.askSz
+	b.haveTop = true
+	b.bidPx, b.bidSz, b.askPx, b.askSz = bidPx, bidSz, askPx, askSz
+
+	if changed && b.bound {
+		b.matrix.WriteBBO(b.exchangeID, b.symbolID, upd.Ts, bidPx, bidSz, askPx, askSz)
+	}
+	return nil
+}
+
+// BestBidAsk returns the current top of book, if any update has been applied.
+func (b *StreamBook) BestBidAsk() (bidPx, bidSz, askPx, askSz float64, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.bidPx, b.bidSz, b.askPx, b.askSz, b.haveTop
+}
+
+// applySide merges diff levels into a side's price→size map. A zero size
+// removes the level, matching the standard exchange depth-diff convention.
+func applySide(side map[float64]float64, levels []PriceLevel) {
+	for _, lvl := range levels {
+		if lvl.Size == 0 {
+			delete(side, lvl.Price)
+			continue
+		}
+		side[lvl.Price] = lvl.Size
+	}
+}
+
+// bestOf scans a side for its best price (highest for bids, lowest for
+// asks). Books stay shallow in practice (tens of levels), so a linear scan
+// is cheaper here than maintaining a sorted structure on every update.
+func bestOf(side map[float64]float64, wantMax bool) (price, size float64, ok bool) {
+	first := true
+	for px, sz := range side {
+		if first || (wantMax && px > price) || (!wantMax && px < price) {
+			price, size, first = px, sz, false
+			ok = true
+		}
+	}
+	return price, size, ok
+}
@@ -11,12 +11,28 @@ type Config struct {
 }
 
 type ExchangeConfig struct {
-	Enabled bool              `toml:"enabled"`
-	Testnet bool              `toml:"testnet"`
-	WSURL   string            `toml:"ws_url"`
-	RESTURL string            `toml:"rest_url"`
+	Enabled bool   `toml:"enabled"`
+	Testnet bool   `toml:"testnet"`
+	WSURL   string `toml:"ws_url"`
+	RESTURL string `toml:"rest_url"`
 	// Symbols maps standard local symbol (e.g. "BTC") to exchange-specific ID (e.g. "BTC_USDC_PERP")
 	Symbols map[string]string `toml:"symbols"`
+
+	// ReplayCapture, if set, makes this exchange run from a feeder/replay
+	// capture file instead of dialing live — a deterministic stand-in for
+	// integration/regression tests, filling the role MockFeeder otherwise
+	// would.
+	ReplayCapture string `toml:"replay_capture"`
+	// ReplaySpeed scales the replay's inter-message pacing (1.0 = original
+	// speed, 2.0 = twice as fast). 0, the unset value, is treated as 1.0.
+	// Ignored unless ReplayCapture is set.
+	ReplaySpeed float64 `toml:"replay_speed"`
+
+	// RecordCapture, if set, makes this exchange run live as normal but tee
+	// every inbound frame to a feeder/replay capture file at this path — the
+	// capture a later ReplayCapture run (or a test) can redrive. Mutually
+	// exclusive with ReplayCapture.
+	RecordCapture string `toml:"record_capture"`
 }
 
 func Load(path string) (*Config, error) {
@@ -4,143 +4,142 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"strconv"
 	"time"
 
+	"github.com/AlephTX/aleph-tx/feeder/book"
+	"github.com/AlephTX/aleph-tx/feeder/config"
 	"github.com/AlephTX/aleph-tx/feeder/shm"
 	"nhooyr.io/websocket"
 )
 
+const backpackDefaultWSURL = "wss://ws.backpack.exchange"
+
+// backpackDefaultSymbols is used when the config doesn't list any symbols.
+var backpackDefaultSymbols = map[string]string{
+	"BTC": "BTC_USDC_PERP",
+	"ETH": "ETH_USDC_PERP",
+}
+
 // Backpack connects to the Backpack (formerly Coral) exchange.
 type Backpack struct {
-	matrix *shm.Matrix
+	cfg    config.ExchangeConfig
+	symMap map[string]uint16 // raw exchange symbol → our symbol ID
+	books  map[string]*book.StreamBook
 }
 
-func NewBackpack(matrix *shm.Matrix) *Backpack {
-	return &Backpack{matrix: matrix}
+func NewBackpack(cfg config.ExchangeConfig, matrix *shm.Matrix) *Backpack {
+	rawSymbols := cfg.Symbols
+	if len(rawSymbols) == 0 {
+		rawSymbols = backpackDefaultSymbols
+	}
+
+	symMap := BuildReverseSymbolMap(rawSymbols)
+	books := make(map[string]*book.StreamBook, len(symMap))
+	for rawSym, symID := range symMap {
+		sb := book.NewStreamBook(rawSym, "backpack")
+		sb.BindStream(matrix, ExchangeBackpack, symID)
+		books[rawSym] = sb
+	}
+
+	return &Backpack{cfg: cfg, symMap: symMap, books: books}
+}
+
+func init() {
+	Register("backpack", func(cfg config.ExchangeConfig, m *shm.Matrix) (Exchange, error) {
+		return NewBackpack(cfg, m), nil
+	})
 }
 
 // Backpack depth message
 type backpackDepth struct {
-	EventType string           `json:"e"`
-	Symbol    string           `json:"s"`
-	Timestamp int64            `json:"T"`
-	Bids      [][]string       `json:"b"` // [price, size]
-	Asks      [][]string       `json:"a"` // [price, size]
+	EventType string     `json:"e"`
+	Symbol    string     `json:"s"`
+	Timestamp int64      `json:"T"`
+	Bids      [][]string `json:"b"` // [price, size]
+	Asks      [][]string `json:"a"` // [price, size]
 }
 
 func (b *Backpack) Run(ctx context.Context) error {
-	for {
-		if err := b.connect(ctx); err != nil {
-			if ctx.Err() != nil {
-				return ctx.Err()
-			}
-			log.Printf("backpack: disconnected (%v), reconnecting in 3s...", err)
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(3 * time.Second):
-			}
-		}
-	}
+	return RunWebsocketAdapter(ctx, b, b.books)
 }
 
-func (b *Backpack) connect(ctx context.Context) error {
-	c, _, err := websocket.Dial(ctx, "wss://ws.backpack.exchange", nil)
-	if err != nil {
-		return fmt.Errorf("dial: %w", err)
+// Books exposes the StreamBooks Run writes into, so feeder/replay can
+// redrive a capture through this exact adapter+books pairing.
+func (b *Backpack) Books() map[string]*book.StreamBook { return b.books }
+
+func (b *Backpack) Name() string { return "backpack" }
+
+func (b *Backpack) URL() string {
+	if b.cfg.WSURL != "" {
+		return b.cfg.WSURL
 	}
-	defer c.CloseNow()
+	return backpackDefaultWSURL
+}
 
-	// Subscribe to BTC and ETH perpetual
-	symbols := []string{"BTC_USDC_PERP", "ETH_USDC_PERP"}
-	for _, sym := range symbols {
-		channel := "depth." + sym
+// Subscribe subscribes to the depth channel for every configured symbol.
+func (b *Backpack) Subscribe(ctx context.Context, conn *websocket.Conn) error {
+	for rawSym := range b.symMap {
+		channel := "depth." + rawSym
 		sub := map[string]any{
 			"method": "SUBSCRIBE",
 			"params": []string{channel},
-			"id":    1,
+			"id":     1,
 		}
-		if err := c.Write(ctx, websocket.MessageText, mustJSON(sub)); err != nil {
-			return fmt.Errorf("subscribe %s: %w", sym, err)
+		if err := conn.Write(ctx, websocket.MessageText, mustJSON(sub)); err != nil {
+			return fmt.Errorf("subscribe %s: %w", rawSym, err)
 		}
 	}
-	log.Printf("backpack: connected, subscribed to %v", symbols)
+	return nil
+}
 
-	// Read loop
-	for {
-		_, data, err := c.Read(ctx)
-		if err != nil {
-			return err
-		}
+func (b *Backpack) HandleMessage(data []byte) ([]SymbolBookUpdate, error) {
+	var depth backpackDepth
+	if err := decodeJSON(data, &depth); err != nil {
+		return nil, nil
+	}
+	if depth.EventType != "depth" {
+		return nil, nil
+	}
 
-		var depth backpackDepth
-		if err := json.Unmarshal(data, &depth); err != nil {
-			continue
-		}
+	tsNs := uint64(depth.Timestamp) * 1_000_000 // ms → ns
+	if tsNs == 0 {
+		tsNs = uint64(time.Now().UnixNano())
+	}
 
-		if depth.EventType != "depth" {
-			continue
-		}
+	// Backpack's depth stream is a full-side snapshot on every message, so
+	// every update replaces both sides wholesale. The raw frame carries no
+	// update-id/sequence field to put in Seq (unlike Lighter's offset) —
+	// Snapshot is always true here instead, which already makes
+	// StreamBook.Apply skip gap detection for every update from this venue.
+	upd := book.BookUpdate{
+		Snapshot: true,
+		Bids:     toBackpackLevels(depth.Bids),
+		Asks:     toBackpackLevels(depth.Asks),
+		Ts:       tsNs,
+	}
+	return []SymbolBookUpdate{{Symbol: depth.Symbol, Update: upd}}, nil
+}
 
-		symID := backpackSymbolToID(depth.Symbol)
-		if symID == 0 {
+// toBackpackLevels parses Backpack's [price, size] string pairs into
+// book.PriceLevel, skipping any malformed pair.
+func toBackpackLevels(levels [][]string) []book.PriceLevel {
+	out := make([]book.PriceLevel, 0, len(levels))
+	for _, lvl := range levels {
+		if len(lvl) < 2 {
 			continue
 		}
-
-		if len(depth.Bids) == 0 || len(depth.Asks) == 0 {
+		px, errPx := strconv.ParseFloat(lvl[0], 64)
+		sz, errSz := strconv.ParseFloat(lvl[1], 64)
+		if errPx != nil || errSz != nil {
 			continue
 		}
-
-		bidPx, _ := strconv.ParseFloat(depth.Bids[0][0], 64)
-		bidSz, _ := strconv.ParseFloat(depth.Bids[0][1], 64)
-		askPx, _ := strconv.ParseFloat(depth.Asks[0][0], 64)
-		askSz, _ := strconv.ParseFloat(depth.Asks[0][1], 64)
-
-		tsNs := uint64(depth.Timestamp) * 1_000_000 // ms → ns
-		if tsNs == 0 {
-			tsNs = uint64(time.Now().UnixNano())
-		}
-
-		// Write to shared matrix
-		b.matrix.WriteBBO(ExchangeBackpack, symID, tsNs, bidPx, bidSz, askPx, askSz)
+		out = append(out, book.PriceLevel{Price: px, Size: sz})
 	}
+	return out
 }
 
-// Backpack symbol to our ID
-func backpackSymbolToID(symbol string) uint16 {
-	switch symbol {
-	case "BTC_USDC_PERP":
-		return SymbolBTCPERP
-	case "ETH_USDC_PERP":
-		return SymbolETHPERP
-	default:
-		return 0
-	}
-}
-
-// EdgeX placeholder - API not accessible from this environment
-type EdgeX struct {
-	matrix *shm.Matrix
-}
-
-func NewEdgeX(matrix *shm.Matrix) *EdgeX {
-	return &EdgeX{matrix: matrix}
-}
-
-func (e *EdgeX) Run(ctx context.Context) error {
-	log.Println("edgex: API not accessible, using mock data")
-	
-	// Fall back to mock for now
-	mock := NewMockFeeder(e.matrix, ExchangeEdgeX, "EdgeX")
-	mock.Run(ctx)
-	return nil
-}
-
-
 func mustJSON(v interface{}) []byte {
 	b, _ := json.Marshal(v)
 	return b
 }
-
@@ -2,33 +2,195 @@ package exchanges
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"math/rand"
 	"time"
+
+	"github.com/AlephTX/aleph-tx/feeder/book"
+	"nhooyr.io/websocket"
 )
 
 // Exchange defines the interface for all feed handlers.
 type Exchange interface {
+	// Name identifies the adapter in logs (see Register).
+	Name() string
 	Run(ctx context.Context) error
 }
 
-// ConnectFunc represents the actual websocket or REST connection loop.
-type ConnectFunc func(ctx context.Context) error
+// SymbolBookUpdate pairs a book.BookUpdate with the raw exchange symbol it
+// applies to (e.g. "BTC_USDC_PERP", a Lighter market index, a Hyperliquid
+// coin), so RunWebsocketAdapter can resolve it against an adapter's own
+// books map without HandleMessage needing to know about StreamBook at all.
+type SymbolBookUpdate struct {
+	Symbol string
+	Update book.BookUpdate
+}
+
+// FeedAdapter is the shape every websocket-based exchange integration
+// reduces to once dial/subscribe/read/backoff/shutdown is factored out
+// into RunWebsocketAdapter: adapters only translate raw frames into
+// BookUpdates, they never touch the websocket connection lifecycle.
+type FeedAdapter interface {
+	// Name identifies the adapter in logs.
+	Name() string
+	// URL returns the websocket endpoint to dial.
+	URL() string
+	// Subscribe sends whatever subscribe messages the venue requires over
+	// the just-dialed connection.
+	Subscribe(ctx context.Context, conn *websocket.Conn) error
+	// HandleMessage decodes one raw websocket frame into zero or more book
+	// updates. A frame that isn't a book update (heartbeats, acks, other
+	// channels) should simply return no updates and no error.
+	HandleMessage(data []byte) ([]SymbolBookUpdate, error)
+}
+
+// BookedAdapter is a FeedAdapter that also exposes the StreamBooks it
+// writes into (every concrete adapter type implements it). feeder/replay
+// uses Books to redrive a capture through the exact adapter+books pairing
+// RunWebsocketAdapter would use live, which is what lets a ReplayFeeder
+// fill a given venue's slot for deterministic integration tests instead of
+// MockFeeder's random walk.
+type BookedAdapter interface {
+	FeedAdapter
+	Books() map[string]*book.StreamBook
+}
+
+// RunWebsocketAdapter drives the dial/subscribe/read loop shared by every
+// FeedAdapter and reconnects with jittered exponential backoff on any
+// error, up to maxBackoff. books maps the adapter's own raw symbol strings
+// (as returned by HandleMessage) to the StreamBook that should receive
+// their updates; a symbol with no matching book is silently ignored so
+// adapters don't need to filter their own subscriptions.
+func RunWebsocketAdapter(ctx context.Context, adapter FeedAdapter, books map[string]*book.StreamBook) error {
+	return runWebsocketAdapterLoop(ctx, adapter, books, 0, nil)
+}
+
+// FrameRecorder receives a copy of every inbound frame an adapter reads, in
+// order, so it can be redriven later by feeder/replay.ReplayFeeder. See
+// wsTap, which is the only thing that ever calls it.
+type FrameRecorder interface {
+	RecordFrame(exchangeID uint8, recvTsNs uint64, data []byte) error
+}
+
+// RunWebsocketAdapterRecording is RunWebsocketAdapter, but also tees every
+// inbound frame to rec (tagged exchangeID) via wsTap before it reaches
+// adapter.HandleMessage, so the exact session can be captured for replay
+// without any adapter needing to know recording exists.
+func RunWebsocketAdapterRecording(ctx context.Context, adapter FeedAdapter, books map[string]*book.StreamBook, exchangeID uint8, rec FrameRecorder) error {
+	return runWebsocketAdapterLoop(ctx, adapter, books, exchangeID, rec)
+}
+
+func runWebsocketAdapterLoop(ctx context.Context, adapter FeedAdapter, books map[string]*book.StreamBook, exchangeID uint8, rec FrameRecorder) error {
+	const (
+		initialBackoff = 1 * time.Second
+		maxBackoff     = 30 * time.Second
+	)
+	backoff := initialBackoff
 
-// RunConnectionLoop is a utility that handles the infinite reconnect/backoff loop
-// for feeder exchanges, so individual exchanges don't have to duplicate this logic.
-func RunConnectionLoop(ctx context.Context, name string, connect ConnectFunc) error {
 	for {
-		if err := connect(ctx); err != nil {
-			if ctx.Err() != nil {
-				return ctx.Err()
+		err := runWebsocketAdapterOnce(ctx, adapter, books, exchangeID, rec)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		wait := backoff + time.Duration(rand.Int63n(int64(backoff)/2+1))
+		log.Printf("%s: disconnected (%v), reconnecting in %s...", adapter.Name(), err, wait)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		if backoff < maxBackoff {
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
 			}
-			log.Printf("%s: disconnected (%v), reconnecting in 3s...", name, err)
-			
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(3 * time.Second):
+		}
+	}
+}
+
+// frameReader is the subset of *websocket.Conn that the read loop needs,
+// so wsTap can stand in for a plain connection without the loop caring
+// which one it has.
+type frameReader interface {
+	Read(ctx context.Context) (websocket.MessageType, []byte, error)
+}
+
+// wsTap mirrors every frame read off conn to rec before returning it, so
+// recording a venue's traffic never requires touching that venue's
+// HandleMessage — the tap sits below all adapter-specific decoding, at the
+// one place every adapter already funnels through: the raw Read call.
+type wsTap struct {
+	conn       *websocket.Conn
+	rec        FrameRecorder
+	exchangeID uint8
+}
+
+func (t *wsTap) Read(ctx context.Context) (websocket.MessageType, []byte, error) {
+	typ, data, err := t.conn.Read(ctx)
+	if err != nil {
+		return typ, data, err
+	}
+	if err := t.rec.RecordFrame(t.exchangeID, uint64(time.Now().UnixNano()), data); err != nil {
+		log.Printf("replay: record frame: %v", err)
+	}
+	return typ, data, nil
+}
+
+func runWebsocketAdapterOnce(ctx context.Context, adapter FeedAdapter, books map[string]*book.StreamBook, exchangeID uint8, rec FrameRecorder) error {
+	c, _, err := websocket.Dial(ctx, adapter.URL(), nil)
+	if err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+	defer c.CloseNow()
+
+	if err := adapter.Subscribe(ctx, c); err != nil {
+		return fmt.Errorf("subscribe: %w", err)
+	}
+	log.Printf("%s: connected to %s", adapter.Name(), adapter.URL())
+
+	var reader frameReader = c
+	if rec != nil {
+		reader = &wsTap{conn: c, rec: rec, exchangeID: exchangeID}
+	}
+
+	for {
+		_, data, err := reader.Read(ctx)
+		if err != nil {
+			return err
+		}
+
+		updates, err := adapter.HandleMessage(data)
+		if err != nil {
+			continue
+		}
+		for _, u := range updates {
+			sb, ok := books[u.Symbol]
+			if !ok {
+				continue
+			}
+			if err := sb.Apply(u.Update); err != nil {
+				log.Printf("%s: %s %v", adapter.Name(), u.Symbol, err)
+				if errors.Is(err, book.ErrSequenceGap) {
+					// The book has reset itself and is waiting for a fresh
+					// snapshot; reconnecting forces adapter.Subscribe to run
+					// again, which is what actually produces one.
+					return err
+				}
 			}
 		}
 	}
 }
+
+// decodeJSON unmarshals data into v. A pooled streaming json.Decoder was
+// tried here, but Reset-ing only the underlying reader leaves any decoder
+// left over from a previous, differently-shaped frame: a frame with
+// trailing bytes after its first JSON value leaves that remainder buffered
+// and the decoder returns it for the next, unrelated call instead of
+// decoding the new data. json.Unmarshal has no such state to leak.
+func decodeJSON(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
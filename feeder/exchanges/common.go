@@ -8,15 +8,43 @@ const (
 	ExchangeEdgeX       uint8 = 3
 	Exchange01          uint8 = 4
 	ExchangeBackpack    uint8 = 5
+
+	// ExchangeConsolidated is a synthetic row, not a real venue: feeder/xbook
+	// writes the best bid across every real exchange and the best ask across
+	// every real exchange here so consumers can read one consolidated BBO
+	// per symbol without re-deriving it from the per-exchange rows.
+	ExchangeConsolidated uint8 = 6
 )
 
+// RealExchanges lists every exchange ID that represents an actual venue,
+// i.e. every ID above except ExchangeConsolidated.
+var RealExchanges = []uint8{
+	ExchangeHyperliquid,
+	ExchangeLighter,
+	ExchangeEdgeX,
+	Exchange01,
+	ExchangeBackpack,
+}
+
+// ExchangeIDForName maps a config.toml exchange section name (the same
+// name each adapter registers under, see Register) to its numeric exchange
+// ID, so callers that only have the config key — e.g. main wiring up a
+// feeder/replay.ReplayFeeder for one venue — can still tag recorded frames
+// correctly.
+var ExchangeIDForName = map[string]uint8{
+	"hyperliquid": ExchangeHyperliquid,
+	"lighter":     ExchangeLighter,
+	"edgex":       ExchangeEdgeX,
+	"01":          Exchange01,
+	"backpack":    ExchangeBackpack,
+}
+
 // Symbol IDs — global normalized IDs.
 const (
 	SymbolBTCPERP uint16 = 1001
 	SymbolETHPERP uint16 = 1002
 )
 
-
 // SymbolNameToID maps standard local ticker names to our global symbol IDs.
 var SymbolNameToID = map[string]uint16{
 	"BTC": SymbolBTCPERP,
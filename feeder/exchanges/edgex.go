@@ -2,13 +2,12 @@ package exchanges
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/AlephTX/aleph-tx/feeder/book"
 	"github.com/AlephTX/aleph-tx/feeder/config"
 	"github.com/AlephTX/aleph-tx/feeder/shm"
 	"nhooyr.io/websocket"
@@ -17,16 +16,26 @@ import (
 // EdgeX connects to the EdgeX quote API via WebSocket.
 type EdgeX struct {
 	cfg    config.ExchangeConfig
-	matrix *shm.Matrix
 	symMap map[string]uint16
+	books  map[string]*book.StreamBook // contract ID → local book
 }
 
 func NewEdgeX(cfg config.ExchangeConfig, matrix *shm.Matrix) *EdgeX {
-	return &EdgeX{
-		cfg:    cfg,
-		matrix: matrix,
-		symMap: BuildReverseSymbolMap(cfg.Symbols),
+	symMap := BuildReverseSymbolMap(cfg.Symbols)
+	books := make(map[string]*book.StreamBook, len(symMap))
+	for contractID, symID := range symMap {
+		sb := book.NewStreamBook(contractID, "edgex")
+		sb.BindStream(matrix, ExchangeEdgeX, symID)
+		books[contractID] = sb
 	}
+
+	return &EdgeX{cfg: cfg, symMap: symMap, books: books}
+}
+
+func init() {
+	Register("edgex", func(cfg config.ExchangeConfig, m *shm.Matrix) (Exchange, error) {
+		return NewEdgeX(cfg, m), nil
+	})
 }
 
 type edgexWSEvent struct {
@@ -53,66 +62,65 @@ type edgexOBLevel struct {
 }
 
 func (e *EdgeX) Run(ctx context.Context) error {
-	return RunConnectionLoop(ctx, "edgex", e.connect)
+	return RunWebsocketAdapter(ctx, e, e.books)
 }
 
-func (e *EdgeX) connect(ctx context.Context) error {
-	c, _, err := websocket.Dial(ctx, e.cfg.WSURL, nil)
-	if err != nil {
-		return fmt.Errorf("dial: %w", err)
-	}
-	defer c.CloseNow()
+// Books exposes the StreamBooks Run writes into, so feeder/replay can
+// redrive a capture through this exact adapter+books pairing.
+func (e *EdgeX) Books() map[string]*book.StreamBook { return e.books }
 
-	log.Printf("edgex: connected to %s", e.cfg.WSURL)
+func (e *EdgeX) Name() string { return "edgex" }
+func (e *EdgeX) URL() string  { return e.cfg.WSURL }
 
-	// Subscribe to configured symbols at depth level 15
+// Subscribe subscribes to configured symbols at depth level 15.
+func (e *EdgeX) Subscribe(ctx context.Context, conn *websocket.Conn) error {
 	for _, rawSym := range e.cfg.Symbols {
 		channel := fmt.Sprintf("depth.%s.15", rawSym)
 		sub := map[string]any{
 			"type":    "subscribe",
 			"channel": channel,
 		}
-		if err := c.Write(ctx, websocket.MessageText, mustJSON(sub)); err != nil {
+		if err := conn.Write(ctx, websocket.MessageText, mustJSON(sub)); err != nil {
 			return fmt.Errorf("subscribe %s: %w", channel, err)
 		}
-		log.Printf("edgex: subscribed to %v", channel)
 	}
+	return nil
+}
 
-	for {
-		_, data, err := c.Read(ctx)
-		if err != nil {
-			return err
-		}
-
-		var event edgexWSEvent
-		if err := json.Unmarshal(data, &event); err != nil {
-			continue
-		}
-
-		if event.Type != "quote-event" || !strings.HasPrefix(event.Channel, "depth.") {
-			continue
-		}
-
-		if len(event.Content.Data) == 0 {
-			continue
-		}
+func (e *EdgeX) HandleMessage(data []byte) ([]SymbolBookUpdate, error) {
+	var event edgexWSEvent
+	if err := decodeJSON(data, &event); err != nil {
+		return nil, nil
+	}
 
-		depth := event.Content.Data[0]
-		if len(depth.Bids) == 0 || len(depth.Asks) == 0 {
-			continue
-		}
+	if event.Type != "quote-event" || !strings.HasPrefix(event.Channel, "depth.") {
+		return nil, nil
+	}
+	if len(event.Content.Data) == 0 {
+		return nil, nil
+	}
 
-		bidPx, _ := strconv.ParseFloat(depth.Bids[0].Price, 64)
-		bidSz, _ := strconv.ParseFloat(depth.Bids[0].Size, 64)
-		askPx, _ := strconv.ParseFloat(depth.Asks[0].Price, 64)
-		askSz, _ := strconv.ParseFloat(depth.Asks[0].Size, 64)
+	depth := event.Content.Data[0]
+	upd := book.BookUpdate{
+		Snapshot: event.Content.DataType == "SNAPSHOT",
+		Bids:     toEdgexLevels(depth.Bids),
+		Asks:     toEdgexLevels(depth.Asks),
+		Ts:       uint64(time.Now().UnixNano()),
+	}
+	return []SymbolBookUpdate{{Symbol: depth.ContractID, Update: upd}}, nil
+}
 
-		symID, ok := e.symMap[depth.ContractID]
-		if !ok {
+// toEdgexLevels parses EdgeX's string-encoded levels into book.PriceLevel,
+// skipping any level whose price or size doesn't parse.
+func toEdgexLevels(levels []edgexOBLevel) []book.PriceLevel {
+	out := make([]book.PriceLevel, 0, len(levels))
+	for _, lvl := range levels {
+		px, errPx := strconv.ParseFloat(lvl.Price, 64)
+		sz, errSz := strconv.ParseFloat(lvl.Size, 64)
+		if errPx != nil || errSz != nil {
 			continue
 		}
-
-		tsNs := uint64(time.Now().UnixNano())
-		e.matrix.WriteBBO(ExchangeEdgeX, symID, tsNs, bidPx, bidSz, askPx, askSz)
+		out = append(out, book.PriceLevel{Price: px, Size: sz})
 	}
+	return out
 }
@@ -4,23 +4,54 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"strconv"
 	"time"
 
+	"github.com/AlephTX/aleph-tx/feeder/book"
+	"github.com/AlephTX/aleph-tx/feeder/config"
 	"github.com/AlephTX/aleph-tx/feeder/shm"
 	"nhooyr.io/websocket"
-	"nhooyr.io/websocket/wsjson"
 )
 
+const hyperliquidWSURL = "wss://api.hyperliquid.xyz/ws"
+
+// hyperliquidDefaultSymbols is used when the config doesn't list any
+// symbols. Hyperliquid's coin names are already our local ticker names.
+var hyperliquidDefaultSymbols = map[string]string{
+	"BTC": "BTC",
+	"ETH": "ETH",
+}
+
 // Hyperliquid connects to the Hyperliquid L2 book WebSocket.
 type Hyperliquid struct {
-	matrix *shm.Matrix
-	coins  []string
+	cfg   config.ExchangeConfig
+	coins []string
+	books map[string]*book.StreamBook // coin → local book
+}
+
+func NewHyperliquid(cfg config.ExchangeConfig, matrix *shm.Matrix) *Hyperliquid {
+	rawSymbols := cfg.Symbols
+	if len(rawSymbols) == 0 {
+		rawSymbols = hyperliquidDefaultSymbols
+	}
+
+	symMap := BuildReverseSymbolMap(rawSymbols) // coin → our symbol ID
+	coins := make([]string, 0, len(symMap))
+	books := make(map[string]*book.StreamBook, len(symMap))
+	for coin, symID := range symMap {
+		sb := book.NewStreamBook(coin, "hyperliquid")
+		sb.BindStream(matrix, ExchangeHyperliquid, symID)
+		books[coin] = sb
+		coins = append(coins, coin)
+	}
+
+	return &Hyperliquid{cfg: cfg, coins: coins, books: books}
 }
 
-func NewHyperliquid(matrix *shm.Matrix) *Hyperliquid {
-	return &Hyperliquid{matrix: matrix, coins: []string{"BTC", "ETH"}}
+func init() {
+	Register("hyperliquid", func(cfg config.ExchangeConfig, m *shm.Matrix) (Exchange, error) {
+		return NewHyperliquid(cfg, m), nil
+	})
 }
 
 type hlEnvelope struct {
@@ -29,9 +60,9 @@ type hlEnvelope struct {
 }
 
 type hlL2Book struct {
-	Coin   string       `json:"coin"`
-	Time   int64        `json:"time"`
-	Levels [][]hlLevel  `json:"levels"`
+	Coin   string      `json:"coin"`
+	Time   int64       `json:"time"`
+	Levels [][]hlLevel `json:"levels"`
 }
 
 type hlLevel struct {
@@ -40,29 +71,24 @@ type hlLevel struct {
 }
 
 func (h *Hyperliquid) Run(ctx context.Context) error {
-	for {
-		if err := h.connect(ctx); err != nil {
-			if ctx.Err() != nil {
-				return ctx.Err()
-			}
-			log.Printf("hyperliquid: disconnected (%v), reconnecting in 3s...", err)
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(3 * time.Second):
-			}
-		}
-	}
+	return RunWebsocketAdapter(ctx, h, h.books)
 }
 
-func (h *Hyperliquid) connect(ctx context.Context) error {
-	c, _, err := websocket.Dial(ctx, "wss://api.hyperliquid.xyz/ws", nil)
-	if err != nil {
-		return fmt.Errorf("dial: %w", err)
+// Books exposes the StreamBooks Run writes into, so feeder/replay can
+// redrive a capture through this exact adapter+books pairing.
+func (h *Hyperliquid) Books() map[string]*book.StreamBook { return h.books }
+
+func (h *Hyperliquid) Name() string { return "hyperliquid" }
+
+func (h *Hyperliquid) URL() string {
+	if h.cfg.WSURL != "" {
+		return h.cfg.WSURL
 	}
-	defer c.CloseNow()
+	return hyperliquidWSURL
+}
 
-	// Subscribe to l2Book for each coin
+// Subscribe subscribes to l2Book for each tracked coin.
+func (h *Hyperliquid) Subscribe(ctx context.Context, conn *websocket.Conn) error {
 	for _, coin := range h.coins {
 		sub := map[string]any{
 			"method": "subscribe",
@@ -71,48 +97,57 @@ func (h *Hyperliquid) connect(ctx context.Context) error {
 				"coin": coin,
 			},
 		}
-		if err := wsjson.Write(ctx, c, sub); err != nil {
+		if err := conn.Write(ctx, websocket.MessageText, mustJSON(sub)); err != nil {
 			return fmt.Errorf("subscribe %s: %w", coin, err)
 		}
 	}
-	log.Printf("hyperliquid: connected, subscribed to %v", h.coins)
+	return nil
+}
 
-	for {
-		var raw json.RawMessage
-		if err := wsjson.Read(ctx, c, &raw); err != nil {
-			return err
-		}
+func (h *Hyperliquid) HandleMessage(data []byte) ([]SymbolBookUpdate, error) {
+	var env hlEnvelope
+	if err := decodeJSON(data, &env); err != nil {
+		return nil, nil
+	}
+	if env.Channel != "l2Book" {
+		return nil, nil
+	}
 
-		var env hlEnvelope
-		if json.Unmarshal(raw, &env) != nil || env.Channel != "l2Book" {
-			continue
-		}
+	var l2 hlL2Book
+	if err := decodeJSON(env.Data, &l2); err != nil {
+		return nil, nil
+	}
+	if len(l2.Levels) < 2 {
+		return nil, nil
+	}
 
-		var book hlL2Book
-		if json.Unmarshal(env.Data, &book) != nil {
-			continue
-		}
+	tsNs := uint64(l2.Time) * 1_000_000 // ms → ns
+	if tsNs == 0 {
+		tsNs = uint64(time.Now().UnixNano())
+	}
 
-		symID, ok := CoinToSymbolID[book.Coin]
-		if !ok || len(book.Levels) < 2 {
-			continue
-		}
+	// Hyperliquid's l2Book push is a full-depth snapshot every time, so
+	// every message replaces both sides wholesale.
+	upd := book.BookUpdate{
+		Snapshot: true,
+		Bids:     toHlLevels(l2.Levels[0]),
+		Asks:     toHlLevels(l2.Levels[1]),
+		Ts:       tsNs,
+	}
+	return []SymbolBookUpdate{{Symbol: l2.Coin, Update: upd}}, nil
+}
 
-		bids := book.Levels[0]
-		asks := book.Levels[1]
-		if len(bids) == 0 || len(asks) == 0 {
+// toHlLevels parses Hyperliquid's string-encoded levels into
+// book.PriceLevel, skipping any level whose price or size doesn't parse.
+func toHlLevels(levels []hlLevel) []book.PriceLevel {
+	out := make([]book.PriceLevel, 0, len(levels))
+	for _, lvl := range levels {
+		px, errPx := strconv.ParseFloat(lvl.Px, 64)
+		sz, errSz := strconv.ParseFloat(lvl.Sz, 64)
+		if errPx != nil || errSz != nil {
 			continue
 		}
-
-		bidPx, _ := strconv.ParseFloat(bids[0].Px, 64)
-		bidSz, _ := strconv.ParseFloat(bids[0].Sz, 64)
-		askPx, _ := strconv.ParseFloat(asks[0].Px, 64)
-		askSz, _ := strconv.ParseFloat(asks[0].Sz, 64)
-
-		tsNs := uint64(book.Time) * 1_000_000 // ms → ns
-
-		// Write to shared matrix (triggers version increment)
-		h.matrix.WriteBBO(ExchangeHyperliquid, symID, tsNs,
-			bidPx, bidSz, askPx, askSz)
+		out = append(out, book.PriceLevel{Price: px, Size: sz})
 	}
+	return out
 }
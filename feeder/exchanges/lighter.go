@@ -4,10 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"strconv"
 	"time"
 
+	"github.com/AlephTX/aleph-tx/feeder/book"
+	"github.com/AlephTX/aleph-tx/feeder/config"
 	"github.com/AlephTX/aleph-tx/feeder/shm"
 	"nhooyr.io/websocket"
 )
@@ -18,13 +19,31 @@ var lighterMarkets = map[int]uint16{
 	0: SymbolETHPERP, // market 0 = ETH
 }
 
+const lighterDefaultWSURL = "wss://mainnet.zklighter.elliot.ai/stream"
+
 // Lighter connects to the Lighter (zkLighter) orderbook WebSocket.
 type Lighter struct {
-	ring *shm.RingBuffer
+	cfg   config.ExchangeConfig
+	books map[string]*book.StreamBook // market index (as string) → local book
+}
+
+func NewLighter(cfg config.ExchangeConfig, matrix *shm.Matrix) *Lighter {
+	l := &Lighter{
+		cfg:   cfg,
+		books: make(map[string]*book.StreamBook, len(lighterMarkets)),
+	}
+	for mktIdx, symID := range lighterMarkets {
+		sb := book.NewStreamBook(fmt.Sprintf("market-%d", mktIdx), "lighter")
+		sb.BindStream(matrix, ExchangeLighter, symID)
+		l.books[strconv.Itoa(mktIdx)] = sb
+	}
+	return l
 }
 
-func NewLighter(ring *shm.RingBuffer) *Lighter {
-	return &Lighter{ring: ring}
+func init() {
+	Register("lighter", func(cfg config.ExchangeConfig, m *shm.Matrix) (Exchange, error) {
+		return NewLighter(cfg, m), nil
+	})
 }
 
 // lighterOB is the orderbook snapshot/update envelope.
@@ -36,8 +55,9 @@ type lighterOB struct {
 }
 
 type lighterBook struct {
-	Bids []lighterLevel `json:"bids"`
-	Asks []lighterLevel `json:"asks"`
+	Offset uint64         `json:"offset"`
+	Bids   []lighterLevel `json:"bids"`
+	Asks   []lighterLevel `json:"asks"`
 }
 
 type lighterLevel struct {
@@ -46,86 +66,75 @@ type lighterLevel struct {
 }
 
 func (l *Lighter) Run(ctx context.Context) error {
-	for {
-		if err := l.connect(ctx); err != nil {
-			if ctx.Err() != nil {
-				return ctx.Err()
-			}
-			log.Printf("lighter: disconnected (%v), reconnecting in 3s...", err)
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(3 * time.Second):
-			}
-		}
-	}
+	return RunWebsocketAdapter(ctx, l, l.books)
 }
 
-func (l *Lighter) connect(ctx context.Context) error {
-	c, _, err := websocket.Dial(ctx, "wss://mainnet.zklighter.elliot.ai/stream", nil)
-	if err != nil {
-		return fmt.Errorf("dial: %w", err)
+// Books exposes the StreamBooks Run writes into, so feeder/replay can
+// redrive a capture through this exact adapter+books pairing.
+func (l *Lighter) Books() map[string]*book.StreamBook { return l.books }
+
+func (l *Lighter) Name() string { return "lighter" }
+
+func (l *Lighter) URL() string {
+	if l.cfg.WSURL != "" {
+		return l.cfg.WSURL
 	}
-	defer c.CloseNow()
-	c.SetReadLimit(1 << 20) // 1MB — initial snapshot is large
+	return lighterDefaultWSURL
+}
+
+// Subscribe subscribes to BTC (market 1) and ETH (market 0). The initial
+// snapshot frame is large, so the read limit is raised before anything is
+// sent.
+func (l *Lighter) Subscribe(ctx context.Context, conn *websocket.Conn) error {
+	conn.SetReadLimit(1 << 20) // 1MB — initial snapshot is large
 
-	// Subscribe to BTC (market 1) and ETH (market 0)
 	for mktIdx := range lighterMarkets {
 		sub := fmt.Sprintf(`{"type":"subscribe","channel":"order_book/%d"}`, mktIdx)
-		if err := c.Write(ctx, websocket.MessageText, []byte(sub)); err != nil {
+		if err := conn.Write(ctx, websocket.MessageText, []byte(sub)); err != nil {
 			return fmt.Errorf("subscribe market %d: %w", mktIdx, err)
 		}
 	}
-	log.Println("lighter: connected, subscribed to BTC(1) + ETH(0)")
-
-	for {
-		_, data, err := c.Read(ctx)
-		if err != nil {
-			return err
-		}
-
-		var env lighterOB
-		if json.Unmarshal(data, &env) != nil {
-			continue
-		}
-
-		// Both snapshot ("subscribed/order_book") and update ("update/order_book")
-		// carry an order_book field with bids/asks.
-		isSnapshot := env.Type == "subscribed/order_book"
-		isUpdate := env.Type == "update/order_book"
-		if !isSnapshot && !isUpdate {
-			continue
-		}
+	return nil
+}
 
-		var book lighterBook
-		if json.Unmarshal(env.OrderBook, &book) != nil {
-			continue
-		}
+func (l *Lighter) HandleMessage(data []byte) ([]SymbolBookUpdate, error) {
+	var env lighterOB
+	if err := decodeJSON(data, &env); err != nil {
+		return nil, nil
+	}
 
-		// Extract market index from channel: "order_book:0" or subscribe uses "order_book/0"
-		mktIdx := l.parseMarketIndex(env.Channel)
-		symID, ok := lighterMarkets[mktIdx]
-		if !ok {
-			continue
-		}
+	// Both snapshot ("subscribed/order_book") and update ("update/order_book")
+	// carry an order_book field with bids/asks.
+	isSnapshot := env.Type == "subscribed/order_book"
+	isUpdate := env.Type == "update/order_book"
+	if !isSnapshot && !isUpdate {
+		return nil, nil
+	}
 
-		if len(book.Bids) == 0 || len(book.Asks) == 0 {
-			continue
-		}
+	var raw lighterBook
+	if err := decodeJSON(env.OrderBook, &raw); err != nil {
+		return nil, nil
+	}
 
-		bidPx, _ := strconv.ParseFloat(book.Bids[0].Price, 64)
-		bidSz, _ := strconv.ParseFloat(book.Bids[0].Size, 64)
-		askPx, _ := strconv.ParseFloat(book.Asks[0].Price, 64)
-		askSz, _ := strconv.ParseFloat(book.Asks[0].Size, 64)
+	// Extract market index from channel: "order_book:0" or subscribe uses "order_book/0"
+	mktIdx := l.parseMarketIndex(env.Channel)
+	if mktIdx < 0 {
+		return nil, nil
+	}
 
-		tsNs := uint64(env.Timestamp) * 1_000_000 // ms → ns
-		if tsNs == 0 {
-			tsNs = uint64(time.Now().UnixNano())
-		}
+	tsNs := uint64(env.Timestamp) * 1_000_000 // ms → ns
+	if tsNs == 0 {
+		tsNs = uint64(time.Now().UnixNano())
+	}
 
-		l.ring.WriteBBO(ExchangeLighter, symID, tsNs,
-			bidPx, bidSz, askPx, askSz)
+	upd := book.BookUpdate{
+		Snapshot: isSnapshot,
+		Bids:     toPriceLevels(raw.Bids),
+		Asks:     toPriceLevels(raw.Asks),
+		Ts:       tsNs,
+		Seq:      raw.Offset,
 	}
+	return []SymbolBookUpdate{{Symbol: strconv.Itoa(mktIdx), Update: upd}}, nil
 }
 
 // parseMarketIndex extracts the integer from "order_book:N" or "order_book/N".
@@ -139,3 +148,18 @@ func (l *Lighter) parseMarketIndex(channel string) int {
 	}
 	return -1
 }
+
+// toPriceLevels parses Lighter's string-encoded levels into book.PriceLevel,
+// skipping any level whose price or size doesn't parse.
+func toPriceLevels(levels []lighterLevel) []book.PriceLevel {
+	out := make([]book.PriceLevel, 0, len(levels))
+	for _, lvl := range levels {
+		px, errPx := strconv.ParseFloat(lvl.Price, 64)
+		sz, errSz := strconv.ParseFloat(lvl.Size, 64)
+		if errPx != nil || errSz != nil {
+			continue
+		}
+		out = append(out, book.PriceLevel{Price: px, Size: sz})
+	}
+	return out
+}
@@ -0,0 +1,32 @@
+package exchanges
+
+import (
+	"fmt"
+
+	"github.com/AlephTX/aleph-tx/feeder/config"
+	"github.com/AlephTX/aleph-tx/feeder/shm"
+)
+
+// Factory builds one Exchange adapter from its config section and the
+// shared matrix it should publish into.
+type Factory func(cfg config.ExchangeConfig, matrix *shm.Matrix) (Exchange, error)
+
+var registry = make(map[string]Factory)
+
+// Register adds a factory under name for later lookup by New. Each adapter
+// file calls this from its own init(), keyed by the same name used for its
+// section in config.toml, so main never has to know the concrete adapter
+// types.
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+// New builds the adapter registered under name. It returns an error if no
+// adapter has been registered under that name.
+func New(name string, cfg config.ExchangeConfig, matrix *shm.Matrix) (Exchange, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("exchanges: no adapter registered for %q", name)
+	}
+	return factory(cfg, matrix)
+}
@@ -1,81 +1,164 @@
-// Package ipc provides a Unix socket client that connects to the Rust core.
+// Package ipc provides a Unix socket client that streams BBO and signal
+// updates to the Rust core using a length-prefixed binary protocol: a
+// 4-byte little-endian length (covering the type byte and payload), a
+// 1-byte message type, and the payload itself. Message payloads are fixed
+// binary encodings that must match core/src/ipc_decoder.rs exactly, the
+// same convention shm.ShmBboMessage and shm.Signal already follow for
+// their shared-memory layouts.
 package ipc
 
 import (
-	"encoding/json"
+	"encoding/binary"
 	"log"
 	"net"
-	"sync"
-	"time"
+	"sync/atomic"
 )
 
-// Message is the envelope sent over the socket.
-type Message struct {
-	Type    string          `json:"type"`
-	Payload json.RawMessage `json:"payload"`
+// Message types. Payload layouts live alongside the producer that builds
+// them (feeder/shm.ShmBboMessage, feeder/shm.Signal); ipc only frames them.
+const (
+	MsgTypeBBO    uint8 = 1
+	MsgTypeSignal uint8 = 2
+)
+
+const headerSize = 5 // 4-byte length + 1-byte msg type
+
+// defaultQueueSize bounds how many messages can be buffered while the Rust
+// core is unreachable before Publish starts dropping the oldest one.
+const defaultQueueSize = 4096
+
+type queuedMsg struct {
+	msgType uint8
+	payload []byte
+}
+
+// Stats is a snapshot of a Publisher's lifetime counters.
+type Stats struct {
+	BytesSent uint64
+	MsgsSent  uint64
+	Drops     uint64
 }
 
-// Publisher dials the Rust core Unix socket and streams messages to it.
+// Publisher dials the Rust core Unix socket and streams framed messages to
+// it from a single writer goroutine, so a stalled or absent consumer never
+// blocks the feeder goroutines calling Publish. Once the send queue is
+// full, Publish drops the oldest queued message to make room for the new
+// one, counting the drop in Stats.
 type Publisher struct {
-	path string
-	mu   sync.Mutex
-	conn net.Conn
+	path  string
+	queue chan queuedMsg
+	done  chan struct{}
+
+	conn net.Conn // owned exclusively by writeLoop
+
+	bytesSent uint64
+	msgsSent  uint64
+	drops     uint64
 }
 
+// NewPublisher creates a Publisher and starts its writer goroutine. The
+// Rust core doesn't need to be listening yet; the writer dials lazily and
+// retries on every subsequent message until it connects.
 func NewPublisher(path string) (*Publisher, error) {
-	p := &Publisher{path: path}
-	p.dial() // best-effort; Rust may not be ready yet
+	p := &Publisher{
+		path:  path,
+		queue: make(chan queuedMsg, defaultQueueSize),
+		done:  make(chan struct{}),
+	}
+	go p.writeLoop()
 	return p, nil
 }
 
-func (p *Publisher) dial() {
-	conn, err := net.Dial("unix", p.path)
-	if err != nil {
-		return // will retry on next Publish
-	}
-	p.mu.Lock()
-	p.conn = conn
-	p.mu.Unlock()
-	log.Printf("ipc: connected to %s", p.path)
-}
+// Publish enqueues a typed, pre-encoded message for delivery to the Rust
+// core. It never blocks: if the send queue is full, the oldest queued
+// message is dropped to make room.
+func (p *Publisher) Publish(msgType uint8, payload []byte) {
+	msg := queuedMsg{msgType: msgType, payload: payload}
 
-// Publish sends a typed message to the Rust core.
-func (p *Publisher) Publish(msgType string, payload any) {
-	raw, err := json.Marshal(payload)
-	if err != nil {
+	select {
+	case p.queue <- msg:
 		return
+	default:
 	}
-	msg, _ := json.Marshal(Message{Type: msgType, Payload: raw})
-	msg = append(msg, '\n')
-
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	for attempts := 0; attempts < 3; attempts++ {
-		if p.conn == nil {
-			p.mu.Unlock()
-			time.Sleep(500 * time.Millisecond)
-			p.mu.Lock()
-			conn, err := net.Dial("unix", p.path)
-			if err != nil {
-				continue
-			}
-			p.conn = conn
-			log.Printf("ipc: reconnected to %s", p.path)
-		}
-		if _, err := p.conn.Write(msg); err != nil {
-			p.conn.Close()
-			p.conn = nil
-			continue
-		}
-		return
+
+	select {
+	case <-p.queue:
+		atomic.AddUint64(&p.drops, 1)
+	default:
+	}
+
+	select {
+	case p.queue <- msg:
+	default:
+		atomic.AddUint64(&p.drops, 1)
+	}
+}
+
+// Stats returns a snapshot of bytes/messages sent and messages dropped,
+// for observability. Drops counts both messages evicted by queue overflow
+// and messages lost because dialing or writing to the socket failed.
+func (p *Publisher) Stats() Stats {
+	return Stats{
+		BytesSent: atomic.LoadUint64(&p.bytesSent),
+		MsgsSent:  atomic.LoadUint64(&p.msgsSent),
+		Drops:     atomic.LoadUint64(&p.drops),
 	}
 }
 
+// Close stops accepting new messages, flushes whatever is already queued,
+// and closes the socket.
 func (p *Publisher) Close() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	close(p.queue)
+	<-p.done
 	if p.conn != nil {
 		p.conn.Close()
 	}
 }
+
+// writeLoop is the sole owner of p.conn: it drains the queue, dialing or
+// redialing as needed, until Close closes the queue.
+func (p *Publisher) writeLoop() {
+	defer close(p.done)
+
+	for msg := range p.queue {
+		if err := p.writeFramed(msg); err != nil {
+			log.Printf("ipc: write to %s: %v", p.path, err)
+			continue // payload dropped; next message will redial
+		}
+	}
+}
+
+func (p *Publisher) writeFramed(msg queuedMsg) error {
+	if p.conn == nil {
+		conn, err := net.Dial("unix", p.path)
+		if err != nil {
+			atomic.AddUint64(&p.drops, 1)
+			return err
+		}
+		p.conn = conn
+		log.Printf("ipc: connected to %s", p.path)
+	}
+
+	var hdr [headerSize]byte
+	binary.LittleEndian.PutUint32(hdr[0:4], uint32(1+len(msg.payload)))
+	hdr[4] = msg.msgType
+
+	if _, err := p.conn.Write(hdr[:]); err != nil {
+		p.conn.Close()
+		p.conn = nil
+		atomic.AddUint64(&p.drops, 1)
+		return err
+	}
+	if len(msg.payload) > 0 {
+		if _, err := p.conn.Write(msg.payload); err != nil {
+			p.conn.Close()
+			p.conn = nil
+			atomic.AddUint64(&p.drops, 1)
+			return err
+		}
+	}
+
+	atomic.AddUint64(&p.bytesSent, uint64(headerSize+len(msg.payload)))
+	atomic.AddUint64(&p.msgsSent, 1)
+	return nil
+}
@@ -10,7 +10,9 @@ import (
 
 	"github.com/AlephTX/aleph-tx/feeder/config"
 	"github.com/AlephTX/aleph-tx/feeder/exchanges"
+	"github.com/AlephTX/aleph-tx/feeder/replay"
 	"github.com/AlephTX/aleph-tx/feeder/shm"
+	"github.com/AlephTX/aleph-tx/feeder/xbook"
 )
 
 func main() {
@@ -34,76 +36,105 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	// Create shared memory matrix (~656 KB)
+	// Create shared memory matrix (~912 KB)
 	matrix, err := shm.NewMatrix(shmName)
 	if err != nil {
 		log.Fatalf("shm: %v", err)
 	}
 	defer matrix.Close()
-	log.Printf("📡 Shared matrix: /dev/shm/%s (~656 KB)", shmName)
+	log.Printf("📡 Shared matrix: /dev/shm/%s (~912 KB)", shmName)
 
-	var wg sync.WaitGroup
+	// Suppress bad ticks (crossed books, outsized jumps, stale exchanges)
+	// before they ever reach the Rust consumer.
+	matrix.SetBreaker(shm.NewCircuitBreaker(nil))
 
-	if hlCfg, ok := cfg.Exchanges["hyperliquid"]; ok && hlCfg.Enabled {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			hl := exchanges.NewHyperliquid(hlCfg, matrix)
-			log.Println("🔌 Hyperliquid: starting...")
-			if err := hl.Run(ctx); err != nil && err != context.Canceled {
-				log.Printf("Hyperliquid: %v", err)
-			}
-		}()
+	signalRingName := "aleph-signals"
+	if s := os.Getenv("ALEPH_SIGNAL_RING"); s != "" {
+		signalRingName = s
 	}
-
-	if ltCfg, ok := cfg.Exchanges["lighter"]; ok && ltCfg.Enabled {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			lt := exchanges.NewLighter(ltCfg, matrix)
-			log.Println("🔌 Lighter: starting...")
-			if err := lt.Run(ctx); err != nil && err != context.Canceled {
-				log.Printf("Lighter: %v", err)
-			}
-		}()
+	signalRing, err := shm.NewSignalRing(signalRingName, 1024)
+	if err != nil {
+		log.Fatalf("shm signal ring: %v", err)
 	}
+	defer signalRing.Close()
+	log.Printf("📡 Signal ring: /dev/shm/%s", signalRingName)
 
-	if bpCfg, ok := cfg.Exchanges["backpack"]; ok && bpCfg.Enabled {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			bp := exchanges.NewBackpack(bpCfg, matrix)
-			log.Println("🔌 Backpack: starting...")
-			if err := bp.Run(ctx); err != nil && err != context.Canceled {
-				log.Printf("Backpack: %v", err)
+	var wg sync.WaitGroup
+	var recorders []*replay.Recorder
+
+	// Every adapter self-registers under the same name as its config.toml
+	// section (see exchanges.Register), so starting the configured venues
+	// is just iterating the loaded config — adding a new venue only means
+	// adding its adapter file, not touching main.
+	for name, excCfg := range cfg.Exchanges {
+		if !excCfg.Enabled {
+			continue
+		}
+		ex, err := exchanges.New(name, excCfg, matrix)
+		if err != nil {
+			log.Fatalf("exchanges: %v", err)
+		}
+
+		if excCfg.ReplayCapture != "" {
+			ba, ok := ex.(exchanges.BookedAdapter)
+			if !ok {
+				log.Fatalf("replay: %s adapter does not support replay (no Books())", name)
 			}
-		}()
-	}
+			exID, ok := exchanges.ExchangeIDForName[name]
+			if !ok {
+				log.Fatalf("replay: no exchange ID registered for %q", name)
+			}
+			speed := excCfg.ReplaySpeed
+			if speed == 0 {
+				speed = 1.0
+			}
+			ex = replay.NewReplayFeeder(excCfg.ReplayCapture, ba, ba.Books(), exID, speed)
+			log.Printf("🎞️  %s: replaying capture %s (speed=%.2fx)", name, excCfg.ReplayCapture, speed)
+		} else if excCfg.RecordCapture != "" {
+			ba, ok := ex.(exchanges.BookedAdapter)
+			if !ok {
+				log.Fatalf("replay: %s adapter does not support recording (no Books())", name)
+			}
+			exID, ok := exchanges.ExchangeIDForName[name]
+			if !ok {
+				log.Fatalf("replay: no exchange ID registered for %q", name)
+			}
+			rec, err := replay.NewRecorder(excCfg.RecordCapture)
+			if err != nil {
+				log.Fatalf("replay: %s: %v", name, err)
+			}
+			recorders = append(recorders, rec)
+			ex = replay.NewRecordingFeeder(ba, exID, rec)
+			log.Printf("⏺️  %s: recording live traffic to %s", name, excCfg.RecordCapture)
+		}
 
-	if edgexCfg, ok := cfg.Exchanges["edgex"]; ok && edgexCfg.Enabled {
 		wg.Add(1)
-		go func() {
+		go func(ex exchanges.Exchange) {
 			defer wg.Done()
-			ex := exchanges.NewEdgeX(edgexCfg, matrix)
-			log.Println("🔌 EdgeX: starting...")
+			log.Printf("🔌 %s: starting...", ex.Name())
 			if err := ex.Run(ctx); err != nil && err != context.Canceled {
-				log.Printf("EdgeX: %v", err)
+				log.Printf("%s: %v", ex.Name(), err)
 			}
-		}()
+		}(ex)
 	}
 
-	if zeroOneCfg, ok := cfg.Exchanges["01"]; ok && zeroOneCfg.Enabled {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			z := exchanges.NewZeroOne(zeroOneCfg, matrix)
-			log.Println("🔌 01 Exchange: starting...")
-			if err := z.Run(ctx); err != nil && err != context.Canceled {
-				log.Printf("01: %v", err)
-			}
-		}()
-	}
+	agg := xbook.NewAggregator(matrix, signalRing, xbook.DefaultConfig)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		log.Println("📊 Cross-exchange aggregator: starting...")
+		if err := agg.Run(ctx); err != nil && err != context.Canceled {
+			log.Printf("xbook: %v", err)
+		}
+	}()
 
 	wg.Wait()
+
+	for _, rec := range recorders {
+		if err := rec.Close(); err != nil {
+			log.Printf("replay: close capture: %v", err)
+		}
+	}
+
 	log.Println("👋 Feeder stopped.")
 }
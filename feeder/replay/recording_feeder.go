@@ -0,0 +1,30 @@
+package replay
+
+import (
+	"context"
+
+	"github.com/AlephTX/aleph-tx/feeder/exchanges"
+)
+
+// RecordingFeeder runs a live adapter exactly as exchanges.RunWebsocketAdapter
+// would, except every inbound frame is also teed to a Recorder first — the
+// capture half of the record/replay subsystem ReplayFeeder replays. Wrapping
+// a BookedAdapter this way, rather than adding a recorder parameter to every
+// adapter's own Run, keeps adapters unaware recording exists at all.
+type RecordingFeeder struct {
+	adapter    exchanges.BookedAdapter
+	exchangeID uint8
+	rec        *Recorder
+}
+
+// NewRecordingFeeder builds a RecordingFeeder that runs adapter live and tees
+// every inbound frame (tagged exchangeID) to rec.
+func NewRecordingFeeder(adapter exchanges.BookedAdapter, exchangeID uint8, rec *Recorder) *RecordingFeeder {
+	return &RecordingFeeder{adapter: adapter, exchangeID: exchangeID, rec: rec}
+}
+
+func (r *RecordingFeeder) Name() string { return r.adapter.Name() }
+
+func (r *RecordingFeeder) Run(ctx context.Context) error {
+	return exchanges.RunWebsocketAdapterRecording(ctx, r.adapter, r.adapter.Books(), r.exchangeID, r.rec)
+}
@@ -0,0 +1,166 @@
+package shm
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// BreakerLimits configures the circuit breaker for one symbol.
+type BreakerLimits struct {
+	MaxCrossedBook      time.Duration // how long bid >= ask may persist before it counts against the trip limit
+	MaxTickJumpBps      float64       // max allowed move of mid vs the prior mid, in bps
+	MaxConsecutiveStale int           // consecutive suspect ticks from one exchange before that column gets muted
+}
+
+// DefaultBreakerLimits is used for any symbol without an explicit override
+// in the map passed to NewCircuitBreaker.
+var DefaultBreakerLimits = BreakerLimits{
+	MaxCrossedBook:      500 * time.Millisecond,
+	MaxTickJumpBps:      200, // 2%
+	MaxConsecutiveStale: 50,
+}
+
+// BreakerStats is a snapshot of a (symbol, exchange) pair's trip counters,
+// exposed so operators can watch trip rates without racing on the ring.
+type BreakerStats struct {
+	Suspect uint64 // ticks flagged suspect (crossed book or outsized jump)
+	Dropped uint64 // ticks dropped outright because the column was muted
+	Muted   bool   // true if this (symbol, exchange) is currently muted
+}
+
+type breakerEntry struct {
+	mu sync.Mutex
+
+	crossedSince time.Time
+	lastMid      float64
+	haveMid      bool
+	consecutive  int
+	muted        bool
+
+	suspectCount uint64
+	droppedCount uint64
+}
+
+// verdict is what WriteBBO should do with a tick after consulting the breaker.
+type verdict int
+
+const (
+	verdictPublish verdict = iota // write normally
+	verdictSuspect                // write, but mark the slot MsgTypeSuspect
+	verdictMuted                  // drop: this (symbol, exchange) column is currently muted
+)
+
+// CircuitBreaker guards Matrix.WriteBBO against propagating bad ticks:
+// crossed books, outsized single-tick jumps, and an exchange gone stale.
+// A single breaker tracks every (symbol, exchange) pair independently, so
+// one misbehaving venue never mutes another's feed for the same symbol.
+type CircuitBreaker struct {
+	limits map[uint16]BreakerLimits
+
+	mu      sync.Mutex
+	entries map[uint32]*breakerEntry // key = symbolID<<8 | exchangeID
+}
+
+// NewCircuitBreaker creates a breaker. limits may be nil or partial; any
+// symbol not present falls back to DefaultBreakerLimits.
+func NewCircuitBreaker(limits map[uint16]BreakerLimits) *CircuitBreaker {
+	return &CircuitBreaker{
+		limits:  limits,
+		entries: make(map[uint32]*breakerEntry),
+	}
+}
+
+func breakerKey(symbolID uint16, exchangeID uint8) uint32 {
+	return uint32(symbolID)<<8 | uint32(exchangeID)
+}
+
+func (cb *CircuitBreaker) limitsFor(symbolID uint16) BreakerLimits {
+	if l, ok := cb.limits[symbolID]; ok {
+		return l
+	}
+	return DefaultBreakerLimits
+}
+
+func (cb *CircuitBreaker) entry(symbolID uint16, exchangeID uint8) *breakerEntry {
+	key := breakerKey(symbolID, exchangeID)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	e, ok := cb.entries[key]
+	if !ok {
+		e = &breakerEntry{}
+		cb.entries[key] = e
+	}
+	return e
+}
+
+// evaluate classifies an incoming tick and updates the tracking state for
+// this (symbol, exchange) pair.
+func (cb *CircuitBreaker) evaluate(symbolID uint16, exchangeID uint8, bidPrice, askPrice float64) verdict {
+	limits := cb.limitsFor(symbolID)
+	e := cb.entry(symbolID, exchangeID)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	crossed := bidPrice >= askPrice
+	if crossed {
+		if e.crossedSince.IsZero() {
+			e.crossedSince = now
+		}
+	} else {
+		e.crossedSince = time.Time{}
+	}
+	crossedTooLong := !e.crossedSince.IsZero() && now.Sub(e.crossedSince) > limits.MaxCrossedBook
+
+	mid := (bidPrice + askPrice) / 2
+	var jumpBps float64
+	if e.haveMid && e.lastMid != 0 {
+		jumpBps = math.Abs(mid-e.lastMid) / e.lastMid * 10000
+	}
+	e.lastMid = mid
+	e.haveMid = true
+
+	// crossed alone doesn't make a tick suspect — a momentary cross while
+	// two legs of a book update land a tick apart is normal noise.
+	// MaxCrossedBook is how long a cross is tolerated before it does.
+	suspect := crossedTooLong || jumpBps > limits.MaxTickJumpBps
+
+	if e.muted {
+		if !suspect {
+			// A clean tick clears the mute — the exchange has recovered.
+			e.muted = false
+			e.consecutive = 0
+		} else {
+			e.droppedCount++
+			return verdictMuted
+		}
+	}
+
+	if !suspect {
+		e.consecutive = 0
+		return verdictPublish
+	}
+
+	e.consecutive++
+	e.suspectCount++
+	if e.consecutive >= limits.MaxConsecutiveStale {
+		e.muted = true
+	}
+	return verdictSuspect
+}
+
+// Stats returns a snapshot of the breaker's counters for a (symbol,
+// exchange) pair, for Prometheus-style reporting.
+func (cb *CircuitBreaker) Stats(symbolID uint16, exchangeID uint8) BreakerStats {
+	e := cb.entry(symbolID, exchangeID)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return BreakerStats{
+		Suspect: e.suspectCount,
+		Dropped: e.droppedCount,
+		Muted:   e.muted,
+	}
+}
@@ -7,9 +7,12 @@
 //
 // Memory layout (single mmap, cache-line friendly):
 //   - SymbolVersions[2048]: AtomicU64 per symbol (16 KB, fits in L1d)
-//   - BboMatrix[2048][5]: ShmBboMessage payload (64B × 5 × 2048 = 640 KB)
+//   - BboMatrix[2048][7]: ShmBboMessage payload (64B × 7 × 2048 = 896 KB)
 //
-// Total: ~656 KB
+// Column 6 of BboMatrix (exchanges.ExchangeConsolidated) is a synthetic row
+// written by feeder/xbook, not a real venue.
+//
+// Total: ~912 KB
 package shm
 
 import (
@@ -22,23 +25,34 @@ import (
 
 const (
 	NumSymbols   = 2048
-	NumExchanges = 5
+	NumExchanges = 7
 	SlotSize     = 64 // sizeof(ShmBboMessage)
 )
 
+// consolidatedExchangeID mirrors exchanges.ExchangeConsolidated (column 6,
+// the synthetic row feeder/xbook writes). shm can't import exchanges
+// without an import cycle, so the value is duplicated here; see WriteBBO.
+const consolidatedExchangeID uint8 = 6
+
+// MsgType values for ShmBboMessage.
+const (
+	MsgTypeBBO     uint8 = 1 // normal top-of-book update
+	MsgTypeSuspect uint8 = 2 // published by the circuit breaker: crossed book or outsized jump
+)
+
 // ShmBboMessage is the 64-byte cache-line-aligned BBO message.
 // Layout must match Rust #[repr(C, align(64))] exactly.
 type ShmBboMessage struct {
-	Seqlock      uint32   // 0..4
-	MsgType      uint8    // 4
-	ExchangeID   uint8    // 5
-	SymbolID     uint16   // 6..8
-	TimestampNs  uint64   // 8..16
-	BidPrice     float64  // 16..24
-	BidSize      float64  // 24..32
-	AskPrice     float64  // 32..40
-	AskSize      float64  // 40..48
-	_Reserved    [16]byte // 48..64 padding
+	Seqlock     uint32   // 0..4
+	MsgType     uint8    // 4
+	ExchangeID  uint8    // 5
+	SymbolID    uint16   // 6..8
+	TimestampNs uint64   // 8..16
+	BidPrice    float64  // 16..24
+	BidSize     float64  // 24..32
+	AskPrice    float64  // 32..40
+	AskSize     float64  // 40..48
+	_Reserved   [16]byte // 48..64 padding
 }
 
 // ShmMarketState is the single flat shared memory structure.
@@ -50,7 +64,7 @@ type ShmMarketState struct {
 	SymbolVersions [NumSymbols]uint64
 
 	// BBO matrix: [symbol_id][exchange_id] → ShmBboMessage
-	// Total: 640 KB (2048 × 5 × 64 bytes)
+	// Total: 896 KB (2048 × 7 × 64 bytes)
 	BboMatrix [NumSymbols][NumExchanges]ShmBboMessage
 }
 
@@ -64,8 +78,15 @@ func init() {
 
 // Matrix wraps the shared memory matrix structure.
 type Matrix struct {
-	data []byte
-	shm  *ShmMarketState
+	data    []byte
+	shm     *ShmMarketState
+	breaker *CircuitBreaker
+}
+
+// SetBreaker attaches a circuit breaker that WriteBBO will consult before
+// every publish. Pass nil to disable breaker checks (the default).
+func (m *Matrix) SetBreaker(cb *CircuitBreaker) {
+	m.breaker = cb
 }
 
 // NewMatrix creates or opens a shared memory matrix.
@@ -96,6 +117,16 @@ func NewMatrix(name string) (*Matrix, error) {
 
 // WriteBBO writes a BBO update to the matrix using the seqlock protocol.
 // It also increments the symbol version to notify the Rust reader.
+//
+// If a CircuitBreaker is attached (SetBreaker), it is consulted first: a
+// book crossed for longer than MaxCrossedBook or an outsized single-tick
+// jump is still written, but with MsgType set to MsgTypeSuspect; an
+// exchange column that has produced too many suspect ticks in a row gets
+// muted outright (the write is dropped and the version is not bumped)
+// until it sends a clean tick. The consolidated column (feeder/xbook) is exempt:
+// a genuine cross-exchange arb is exactly a crossed consolidated book, so
+// running it through the same breaker would eventually mute the one column
+// that exists to surface that condition.
 func (m *Matrix) WriteBBO(exchangeID uint8, symbolID uint16, tsNs uint64,
 	bidPrice, bidSize, askPrice, askSize float64) {
 
@@ -104,6 +135,16 @@ func (m *Matrix) WriteBBO(exchangeID uint8, symbolID uint16, tsNs uint64,
 		return
 	}
 
+	msgType := MsgTypeBBO
+	if m.breaker != nil && exchangeID != consolidatedExchangeID {
+		switch m.breaker.evaluate(symbolID, exchangeID, bidPrice, askPrice) {
+		case verdictMuted:
+			return
+		case verdictSuspect:
+			msgType = MsgTypeSuspect
+		}
+	}
+
 	// Get pointers
 	slot := &m.shm.BboMatrix[symbolID][exchangeID]
 	seqAddr := (*uint32)(unsafe.Pointer(&slot.Seqlock))
@@ -113,7 +154,7 @@ func (m *Matrix) WriteBBO(exchangeID uint8, symbolID uint16, tsNs uint64,
 	atomic.StoreUint32(seqAddr, seq+1) // now odd → write in progress
 
 	// Phase 2: write payload
-	slot.MsgType = 1 // BBO
+	slot.MsgType = msgType
 	slot.ExchangeID = exchangeID
 	slot.SymbolID = symbolID
 	slot.TimestampNs = tsNs
@@ -129,6 +170,37 @@ func (m *Matrix) WriteBBO(exchangeID uint8, symbolID uint16, tsNs uint64,
 	atomic.AddUint64(&m.shm.SymbolVersions[symbolID], 1)
 }
 
+// ReadBBO reads the current BBO for (symbolID, exchangeID) using the
+// seqlock protocol, retrying if it catches a writer mid-update. ok is false
+// if the symbol/exchange is out of range or the slot has never been
+// written. Used by feeder/xbook to build the consolidated cross-book
+// without mutating the slots it reads.
+func (m *Matrix) ReadBBO(symbolID uint16, exchangeID uint8) (bidPrice, bidSize, askPrice, askSize float64, tsNs uint64, ok bool) {
+	if symbolID >= NumSymbols || exchangeID >= NumExchanges {
+		return 0, 0, 0, 0, 0, false
+	}
+
+	slot := &m.shm.BboMatrix[symbolID][exchangeID]
+	seqAddr := (*uint32)(unsafe.Pointer(&slot.Seqlock))
+
+	for {
+		seq1 := atomic.LoadUint32(seqAddr)
+		if seq1&1 == 1 {
+			continue // writer in progress, retry
+		}
+
+		msgType := slot.MsgType
+		bidPrice, bidSize = slot.BidPrice, slot.BidSize
+		askPrice, askSize = slot.AskPrice, slot.AskSize
+		tsNs = slot.TimestampNs
+
+		seq2 := atomic.LoadUint32(seqAddr)
+		if seq1 == seq2 {
+			return bidPrice, bidSize, askPrice, askSize, tsNs, msgType != 0
+		}
+	}
+}
+
 // GetVersion returns the current version for a symbol (for diagnostics).
 func (m *Matrix) GetVersion(symbolID uint16) uint64 {
 	if symbolID >= NumSymbols {
@@ -1,114 +1,256 @@
-// Package shm provides a shared memory ring buffer for zero-copy IPC.
+// Package shm also provides a cache-line-aligned, wrap-safe SPSC ring
+// buffer for streaming raw binary messages (used by feeder/binance, which
+// has no per-symbol BBO matrix slot of its own).
 package shm
 
 import (
-	"encoding/binary"
+	"errors"
+	"fmt"
 	"os"
-	"sync"
 	"sync/atomic"
 	"syscall"
+	"unsafe"
 )
 
+// Message type constants for the binance feeder's raw binary records.
 const (
 	MsgTypeTicker = 1
 	MsgTypeDepth  = 2
 )
 
-// RingBuffer is a lock-free single-producer single-consumer ring buffer in shared memory.
+// msgTypeSkip marks a filler record written when a real record would
+// otherwise straddle the end of the ring and have to tear across the wrap.
+// It is never handed back from Read.
+const msgTypeSkip = 0xFF
+
+// recordAlign is the byte alignment every record (header + padded payload)
+// is rounded up to. Because every record occupies a multiple of
+// recordAlign bytes and the ring's capacity is also a multiple of it, the
+// space remaining before the physical end of the buffer is always either
+// zero or big enough to hold at least a recordHeader — so a filler record
+// can always be written instead of a torn one.
+const recordAlign = 16
+
+// recordHeaderSize is sizeof(recordHeader).
+const recordHeaderSize = 16
+
+// recordHeader precedes every record's payload in the ring's data region.
+// Layout must match Rust's equivalent struct for cross-process readers.
+type recordHeader struct {
+	Length  uint32  // payload length in bytes, before alignment padding
+	MsgType uint8   // MsgTypeTicker, MsgTypeDepth, or msgTypeSkip
+	_       [3]byte // padding
+	Seq     uint64  // monotonically increasing record sequence number
+}
+
+func init() {
+	if unsafe.Sizeof(recordHeader{}) != recordHeaderSize {
+		panic(fmt.Sprintf("recordHeader size is %d, expected %d", unsafe.Sizeof(recordHeader{}), recordHeaderSize))
+	}
+}
+
+// ringCursors holds the producer and consumer cursors in the mmap'd region
+// itself, so a consumer in another process can follow along. Each cursor is
+// padded out to its own cache line so the producer writing writeIdx and the
+// consumer writing readIdx never false-share a cache line.
+type ringCursors struct {
+	writeIdx uint64
+	_        [56]byte // pad writeIdx out to 64 bytes
+	readIdx  uint64
+	_        [56]byte // pad readIdx out to 64 bytes
+}
+
+const ringCursorsSize = 128 // sizeof(ringCursors)
+
+func init() {
+	if unsafe.Sizeof(ringCursors{}) != ringCursorsSize {
+		panic(fmt.Sprintf("ringCursors size is %d, expected %d", unsafe.Sizeof(ringCursors{}), ringCursorsSize))
+	}
+}
+
+// ErrRecordTooLarge is returned by Write when payload can never fit in the
+// ring's capacity, regardless of how much of it is free.
+var ErrRecordTooLarge = errors.New("shm: record too large for ring capacity")
+
+// ErrRingFull is returned by Write when the ring currently has no room for
+// the record; the caller should drop the message or retry later.
+var ErrRingFull = errors.New("shm: ring buffer full")
+
+// RingBuffer is a single-producer/single-consumer lock-free ring buffer
+// backed by shared memory. Unlike a naive byte ring, records never tear
+// across the end of the buffer: a record that doesn't fit before
+// end-of-buffer is preceded by a filler record and the write cursor wraps
+// to zero. Reads are non-mutating — Read returns a Record borrowing
+// directly from the shared memory, and the caller must call Commit once
+// it's done with the payload.
 type RingBuffer struct {
-	file     *os.File
+	raw      []byte // cursors header + data region
+	cursors  *ringCursors
 	data     []byte
-	capacity int
-	woff     int64 // write offset (atomic)
-	roff     int64 // read offset (atomic)
-	mu       sync.Mutex
+	capacity int    // len(data), a multiple of recordAlign
+	writeSeq uint64 // producer-local record counter
 }
 
+// NewRingBuffer creates or opens a shared memory ring buffer with room for
+// roughly capacity bytes of records. capacity is rounded up to the nearest
+// multiple of recordAlign.
 func NewRingBuffer(name string, capacity int) (*RingBuffer, error) {
-	// Use /dev/shm for memory-mapped file (backed by RAM)
+	if capacity <= 0 {
+		return nil, fmt.Errorf("shm: capacity must be positive, got %d", capacity)
+	}
+	capacity = alignUp(capacity, recordAlign)
+
 	path := "/dev/shm/" + name
+	size := ringCursorsSize + capacity
+
 	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return nil, err
 	}
-	// Preallocate
-	if err := f.Truncate(int64(capacity)); err != nil {
-		f.Close()
+	defer f.Close()
+
+	if err := f.Truncate(int64(size)); err != nil {
 		return nil, err
 	}
-	data, err := syscall.Mmap(int(f.Fd()), 0, capacity, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+
+	raw, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
 	if err != nil {
-		f.Close()
 		return nil, err
 	}
+
 	return &RingBuffer{
-		file:     f,
-		data:     data,
+		raw:      raw,
+		cursors:  (*ringCursors)(unsafe.Pointer(&raw[0])),
+		data:     raw[ringCursorsSize:],
 		capacity: capacity,
 	}, nil
 }
 
+func alignUp(n, align int) int {
+	return (n + align - 1) &^ (align - 1)
+}
+
+// Write appends one record to the ring. It must only ever be called from
+// the single producer goroutine/process.
 func (r *RingBuffer) Write(msgType byte, payload []byte) error {
-	msgLen := 1 + 2 + len(payload) // 1 byte type + 2 byte length + payload
-	if msgLen > r.capacity {
-		return nil // message too large
+	padded := alignUp(len(payload), recordAlign)
+	total := recordHeaderSize + padded
+	if total > r.capacity {
+		return ErrRecordTooLarge
 	}
 
-	woff := atomic.LoadInt64(&r.woff)
-	newWoff := (woff + int64(msgLen)) % int64(r.capacity)
+	for {
+		writeIdx := r.cursors.writeIdx // producer-owned, safe to read directly
+		readIdx := atomic.LoadUint64(&r.cursors.readIdx)
+		used := int(writeIdx - readIdx)
+		free := r.capacity - used
 
-	// Check if we need to wrap (simple version: just fail if not enough space)
-	if newWoff <= woff && r.capacity-int(woff) < msgLen {
-		// wrapped, skip for now
+		physPos := int(writeIdx % uint64(r.capacity))
+		remaining := r.capacity - physPos
+
+		if remaining < total {
+			// Record would straddle the end of the buffer. Fill the
+			// remainder with a skip record and wrap instead of tearing.
+			if free < remaining {
+				return ErrRingFull
+			}
+			r.writeFiller(physPos, remaining)
+			atomic.StoreUint64(&r.cursors.writeIdx, writeIdx+uint64(remaining))
+			continue
+		}
+
+		if free < total {
+			return ErrRingFull
+		}
+
+		r.writeRecord(physPos, msgType, payload, padded)
+		atomic.StoreUint64(&r.cursors.writeIdx, writeIdx+uint64(total))
 		return nil
 	}
+}
 
-	pos := int(woff)
-	r.data[pos] = msgType
-	binary.LittleEndian.PutUint16(r.data[pos+1:], uint16(len(payload)))
-	copy(r.data[pos+3:], payload)
+func (r *RingBuffer) writeRecord(physPos int, msgType byte, payload []byte, padded int) {
+	hdr := (*recordHeader)(unsafe.Pointer(&r.data[physPos]))
+	hdr.Length = uint32(len(payload))
+	hdr.MsgType = msgType
+	hdr.Seq = r.writeSeq
+	r.writeSeq++
+	copy(r.data[physPos+recordHeaderSize:physPos+recordHeaderSize+len(payload)], payload)
+	if pad := padded - len(payload); pad > 0 {
+		clearBytes := r.data[physPos+recordHeaderSize+len(payload) : physPos+recordHeaderSize+padded]
+		for i := range clearBytes {
+			clearBytes[i] = 0
+		}
+	}
+}
 
-	atomic.StoreInt64(&r.woff, newWoff)
-	return nil
+// writeFiller occupies exactly skipLen bytes at physPos with a filler
+// record, so the reader can skip it as a single unit.
+func (r *RingBuffer) writeFiller(physPos, skipLen int) {
+	hdr := (*recordHeader)(unsafe.Pointer(&r.data[physPos]))
+	hdr.Length = uint32(skipLen - recordHeaderSize)
+	hdr.MsgType = msgTypeSkip
+	hdr.Seq = r.writeSeq
 }
 
-func (r *RingBuffer) Read() (msgType byte, payload []byte, ok bool) {
-	roff := atomic.LoadInt64(&r.roff)
-	woff := atomic.LoadInt64(&r.woff)
-	if roff == woff {
-		return 0, nil, false
-	}
+// Record borrows a payload directly from shared memory. The payload slice
+// is only valid until Commit is called — copy it if it needs to outlive
+// that call.
+type Record struct {
+	MsgType byte
+	Seq     uint64
+	Payload []byte
 
-	pos := int(roff)
-	if pos >= len(r.data) {
-		atomic.StoreInt64(&r.roff, 0)
-		return 0, nil, false
-	}
+	ring       *RingBuffer
+	newReadIdx uint64
+}
 
-	msgType = r.data[pos]
-	if msgType == 0 {
-		// empty slot
-		return 0, nil, false
-	}
-	msgLen := int(binary.LittleEndian.Uint16(r.data[pos+1:]))
-	if msgLen > len(r.data)-3-pos || msgLen < 0 {
-		// invalid, reset
-		atomic.StoreInt64(&r.roff, 0)
-		return 0, nil, false
-	}
+// Commit advances the ring's read cursor past this record, making its
+// space available to the producer again. It must be called exactly once
+// per Record returned by Read, and only from the single consumer
+// goroutine/process.
+func (rec Record) Commit() {
+	atomic.StoreUint64(&rec.ring.cursors.readIdx, rec.newReadIdx)
+}
 
-	payload = make([]byte, msgLen)
-	copy(payload, r.data[pos+3:pos+3+msgLen])
+// Read returns the next unread record without modifying any record bytes in
+// shared memory. It skips over filler records transparently; skipping one
+// advances the read cursor itself, which is the only shared state Read
+// writes. ok is false if the ring is
+// currently empty. Must only ever be called from the single consumer
+// goroutine/process.
+func (r *RingBuffer) Read() (rec Record, ok bool) {
+	for {
+		readIdx := r.cursors.readIdx // consumer-owned, safe to read directly
+		writeIdx := atomic.LoadUint64(&r.cursors.writeIdx)
+		if readIdx == writeIdx {
+			return Record{}, false
+		}
 
-	// clear slot
-	r.data[pos] = 0
+		physPos := int(readIdx % uint64(r.capacity))
+		hdr := (*recordHeader)(unsafe.Pointer(&r.data[physPos]))
+		length := hdr.Length
+		msgType := hdr.MsgType
+		seq := hdr.Seq
+		total := recordHeaderSize + alignUp(int(length), recordAlign)
 
-	newRoff := (roff + 1 + 2 + int64(msgLen)) % int64(r.capacity)
-	atomic.StoreInt64(&r.roff, newRoff)
-	return msgType, payload, true
+		if msgType == msgTypeSkip {
+			atomic.StoreUint64(&r.cursors.readIdx, readIdx+uint64(total))
+			continue
+		}
+
+		payload := r.data[physPos+recordHeaderSize : physPos+recordHeaderSize+int(length)]
+		return Record{
+			MsgType:    msgType,
+			Seq:        seq,
+			Payload:    payload,
+			ring:       r,
+			newReadIdx: readIdx + uint64(total),
+		}, true
+	}
 }
 
+// Close unmaps the shared memory.
 func (r *RingBuffer) Close() error {
-	syscall.Munmap(r.data)
-	return r.file.Close()
+	return syscall.Munmap(r.raw)
 }
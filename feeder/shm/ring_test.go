@@ -0,0 +1,154 @@
+package shm
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func newTestRing(t *testing.T, capacity int) *RingBuffer {
+	t.Helper()
+	name := fmt.Sprintf("aleph-ring-test-%d", rand.Int63())
+	r, err := NewRingBuffer(name, capacity)
+	if err != nil {
+		t.Fatalf("NewRingBuffer: %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestRingBufferWriteRead(t *testing.T) {
+	r := newTestRing(t, 4096)
+
+	payload := []byte("hello aleph")
+	if err := r.Write(MsgTypeTicker, payload); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	rec, ok := r.Read()
+	if !ok {
+		t.Fatal("Read: expected a record, got none")
+	}
+	if rec.MsgType != MsgTypeTicker {
+		t.Errorf("MsgType = %d, want %d", rec.MsgType, MsgTypeTicker)
+	}
+	if string(rec.Payload) != string(payload) {
+		t.Errorf("Payload = %q, want %q", rec.Payload, payload)
+	}
+	rec.Commit()
+
+	if _, ok := r.Read(); ok {
+		t.Fatal("Read: expected ring to be empty after Commit")
+	}
+}
+
+// TestRingBufferWrapsWithoutTearing forces many wraps around a small
+// capacity with varying payload sizes and checks every record comes back
+// intact, in order, with no corruption from the wrap/filler logic.
+func TestRingBufferWrapsWithoutTearing(t *testing.T) {
+	r := newTestRing(t, 256)
+
+	const n = 5000
+	sizes := []int{1, 3, 7, 15, 16, 31, 64, 100}
+
+	produced := 0
+	consumed := 0
+	for produced < n || consumed < produced {
+		if produced < n {
+			payload := make([]byte, sizes[produced%len(sizes)])
+			for i := range payload {
+				payload[i] = byte(produced)
+			}
+			if err := r.Write(MsgTypeDepth, payload); err == nil {
+				produced++
+			} else if err != ErrRingFull {
+				t.Fatalf("Write: %v", err)
+			}
+		}
+
+		rec, ok := r.Read()
+		if !ok {
+			continue
+		}
+		want := byte(consumed)
+		for i, b := range rec.Payload {
+			if b != want {
+				t.Fatalf("record %d byte %d = %d, want %d (torn record)", consumed, i, b, want)
+			}
+		}
+		rec.Commit()
+		consumed++
+	}
+
+	if consumed != n {
+		t.Fatalf("consumed %d records, want %d", consumed, n)
+	}
+}
+
+// TestRingBufferConcurrentProducerConsumer stresses the ring with a real
+// goroutine on each side of the SPSC contract and checks every record is
+// delivered exactly once, in order, with the expected contents.
+func TestRingBufferConcurrentProducerConsumer(t *testing.T) {
+	r := newTestRing(t, 64*1024)
+
+	const n = 200_000
+	done := make(chan error, 1)
+
+	go func() {
+		for i := 0; i < n; i++ {
+			payload := []byte(fmt.Sprintf("msg-%d", i))
+			for {
+				if err := r.Write(MsgTypeTicker, payload); err == nil {
+					break
+				} else if err != ErrRingFull {
+					done <- err
+					return
+				}
+			}
+		}
+		done <- nil
+	}()
+
+	for i := 0; i < n; i++ {
+		var rec Record
+		var ok bool
+		for !ok {
+			rec, ok = r.Read()
+		}
+		want := fmt.Sprintf("msg-%d", i)
+		if string(rec.Payload) != want {
+			t.Fatalf("record %d = %q, want %q", i, rec.Payload, want)
+		}
+		if rec.Seq != uint64(i) {
+			t.Fatalf("record %d has Seq %d, want %d (out of order or torn)", i, rec.Seq, i)
+		}
+		rec.Commit()
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("producer: %v", err)
+	}
+}
+
+func BenchmarkRingBufferWriteRead(b *testing.B) {
+	name := fmt.Sprintf("aleph-ring-bench-%d", rand.Int63())
+	r, err := NewRingBuffer(name, 1<<20)
+	if err != nil {
+		b.Fatalf("NewRingBuffer: %v", err)
+	}
+	defer r.Close()
+
+	payload := make([]byte, 64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for r.Write(MsgTypeTicker, payload) == ErrRingFull {
+			if rec, ok := r.Read(); ok {
+				rec.Commit()
+			}
+		}
+		if rec, ok := r.Read(); ok {
+			rec.Commit()
+		}
+	}
+}
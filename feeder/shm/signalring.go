@@ -0,0 +1,122 @@
+package shm
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+// Signal is a single arbitrage opportunity: SellEx's bid can be filled by
+// buying at BuyEx's ask for EdgeBps more than it cost, as of TsNs.
+// Layout must match the Rust-side reference decoder exactly.
+type Signal struct {
+	Seqlock  uint32  // 0..4
+	SymbolID uint16  // 4..6
+	BuyEx    uint8   // 6
+	SellEx   uint8   // 7
+	EdgeBps  float64 // 8..16
+	TsNs     uint64  // 16..24
+}
+
+const signalSlotSize = 24 // sizeof(Signal)
+
+func init() {
+	if unsafe.Sizeof(Signal{}) != signalSlotSize {
+		panic(fmt.Sprintf("Signal size is %d, expected %d", unsafe.Sizeof(Signal{}), signalSlotSize))
+	}
+}
+
+// signalRingCursor holds the producer's write cursor in the mmap'd region
+// itself, mirroring ring.go's ringCursors, so a consumer in another process
+// can find the current head instead of only ever seeing the zero value a
+// Go-local field would leave in shared memory.
+type signalRingCursor struct {
+	writeIdx uint64
+}
+
+const signalRingCursorSize = 8 // sizeof(signalRingCursor)
+
+func init() {
+	if unsafe.Sizeof(signalRingCursor{}) != signalRingCursorSize {
+		panic(fmt.Sprintf("signalRingCursor size is %d, expected %d", unsafe.Sizeof(signalRingCursor{}), signalRingCursorSize))
+	}
+}
+
+// SignalRing is a small single-producer shared-memory ring of Signal
+// records, kept separate from the much larger BBO matrix so a consumer
+// that only cares about arbitrage events doesn't have to scan it.
+//
+// Like Matrix.WriteBBO, a slow or absent consumer never blocks the
+// producer: Emit always advances, so a reader that falls behind simply
+// misses older signals instead of stalling the feeder. Each slot carries
+// its own seqlock (like ShmBboMessage's), since a cross-process reader can
+// otherwise catch Emit mid-write and return a torn Signal.
+type SignalRing struct {
+	raw      []byte // cursor header + slot data
+	cursor   *signalRingCursor
+	data     []byte
+	capacity int // slots
+}
+
+// NewSignalRing creates or opens a shared memory signal ring. slots must be
+// a power of 2.
+func NewSignalRing(name string, slots int) (*SignalRing, error) {
+	if slots <= 0 || slots&(slots-1) != 0 {
+		return nil, fmt.Errorf("slots must be power of 2, got %d", slots)
+	}
+	path := "/dev/shm/" + name
+	size := signalRingCursorSize + slots*signalSlotSize
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if err := f.Truncate(int64(size)); err != nil {
+		return nil, fmt.Errorf("truncate: %w", err)
+	}
+
+	raw, err := syscall.Mmap(int(f.Fd()), 0, size,
+		syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("mmap: %w", err)
+	}
+
+	return &SignalRing{
+		raw:      raw,
+		cursor:   (*signalRingCursor)(unsafe.Pointer(&raw[0])),
+		data:     raw[signalRingCursorSize:],
+		capacity: slots,
+	}, nil
+}
+
+// Emit writes sig into the next ring slot, overwriting the oldest one once
+// the ring has wrapped. It follows the same seqlock protocol as
+// Matrix.WriteBBO: the slot's Seqlock is odd while the payload fields are
+// being written and even once it's safe to read, so a cross-process reader
+// that retries on an odd or changed Seqlock never observes a torn Signal.
+func (r *SignalRing) Emit(sig Signal) {
+	idx := atomic.AddUint64(&r.cursor.writeIdx, 1) - 1
+	offset := int(idx&uint64(r.capacity-1)) * signalSlotSize
+	slot := (*Signal)(unsafe.Pointer(&r.data[offset]))
+	seqAddr := (*uint32)(unsafe.Pointer(&slot.Seqlock))
+
+	seq := atomic.LoadUint32(seqAddr)
+	atomic.StoreUint32(seqAddr, seq+1) // now odd -> write in progress
+
+	slot.SymbolID = sig.SymbolID
+	slot.BuyEx = sig.BuyEx
+	slot.SellEx = sig.SellEx
+	slot.EdgeBps = sig.EdgeBps
+	slot.TsNs = sig.TsNs
+
+	atomic.StoreUint32(seqAddr, seq+2) // now even -> write complete
+}
+
+// Close unmaps the shared memory.
+func (r *SignalRing) Close() error {
+	return syscall.Munmap(r.raw)
+}
@@ -0,0 +1,141 @@
+// Package xbook reads the shm.Matrix BBO rows for every real exchange and,
+// per symbol, republishes the consolidated best bid/ask into a reserved
+// matrix column and emits arbitrage signals when one exchange's bid crosses
+// another's ask. This stays on the read side of the feeder, mirroring
+// bbgo's crossExchangeStrategies/xmaker concept, so no strategy code needs
+// to poll every symbol itself.
+package xbook
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlephTX/aleph-tx/feeder/exchanges"
+	"github.com/AlephTX/aleph-tx/feeder/shm"
+)
+
+// Config tunes the aggregator's sensitivity.
+type Config struct {
+	MinEdgeBps float64       // minimum bid/ask edge required to emit a signal
+	Cooldown   time.Duration // minimum time between signals for the same (symbol, buyEx, sellEx)
+	PollEvery  time.Duration // how often to check SymbolVersions for changes
+}
+
+// DefaultConfig is a conservative starting point: a few bps of edge and a
+// short cooldown so a single quote crossing doesn't re-fire on every tick.
+var DefaultConfig = Config{
+	MinEdgeBps: 5,
+	Cooldown:   2 * time.Second,
+	PollEvery:  5 * time.Millisecond,
+}
+
+type pairKey struct {
+	symbolID uint16
+	buyEx    uint8
+	sellEx   uint8
+}
+
+// Aggregator watches shm.Matrix.SymbolVersions and, per symbol, recomputes
+// the consolidated cross-book whenever the symbol changed since the last
+// scan — piggybacking on the same wake-up signal the Rust core spins on
+// instead of polling every symbol on every tick.
+type Aggregator struct {
+	matrix *shm.Matrix
+	ring   *shm.SignalRing
+	cfg    Config
+
+	lastVersion [shm.NumSymbols]uint64
+	lastSignal  map[pairKey]time.Time
+}
+
+// NewAggregator creates an aggregator that writes the consolidated BBO into
+// matrix and emits arbitrage signals onto ring.
+func NewAggregator(matrix *shm.Matrix, ring *shm.SignalRing, cfg Config) *Aggregator {
+	return &Aggregator{
+		matrix:     matrix,
+		ring:       ring,
+		cfg:        cfg,
+		lastSignal: make(map[pairKey]time.Time),
+	}
+}
+
+// Run polls symbol versions until ctx is done, recomputing the cross-book
+// for every symbol that changed since the last poll.
+func (a *Aggregator) Run(ctx context.Context) error {
+	ticker := time.NewTicker(a.cfg.PollEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			for symID := uint16(0); symID < shm.NumSymbols; symID++ {
+				v := a.matrix.GetVersion(symID)
+				if v == 0 || v == a.lastVersion[symID] {
+					continue
+				}
+				a.update(symID)
+				// Read back the post-update version: update's own consolidated
+				// write bumps it again, so capturing v here would make every
+				// active symbol look "changed" on the very next poll forever.
+				a.lastVersion[symID] = a.matrix.GetVersion(symID)
+			}
+		}
+	}
+}
+
+// update recomputes the best bid and best ask across every real exchange
+// for one symbol, republishes the consolidated BBO, and — if the best bid
+// comes from a different exchange than the best ask and the edge clears
+// MinEdgeBps — emits an arbitrage signal.
+func (a *Aggregator) update(symbolID uint16) {
+	var bestBidPx, bestBidSz, bestAskPx, bestAskSz float64
+	var bestBidEx, bestAskEx uint8
+	var haveBid, haveAsk bool
+	var ts uint64
+
+	for _, ex := range exchanges.RealExchanges {
+		bidPx, bidSz, askPx, askSz, tsNs, ok := a.matrix.ReadBBO(symbolID, ex)
+		if !ok {
+			continue
+		}
+		if !haveBid || bidPx > bestBidPx {
+			bestBidPx, bestBidSz, bestBidEx, haveBid = bidPx, bidSz, ex, true
+		}
+		if !haveAsk || askPx < bestAskPx {
+			bestAskPx, bestAskSz, bestAskEx, haveAsk = askPx, askSz, ex, true
+		}
+		if tsNs > ts {
+			ts = tsNs
+		}
+	}
+	if !haveBid || !haveAsk {
+		return
+	}
+
+	a.matrix.WriteBBO(exchanges.ExchangeConsolidated, symbolID, ts,
+		bestBidPx, bestBidSz, bestAskPx, bestAskSz)
+
+	if bestBidEx == bestAskEx || bestAskPx <= 0 {
+		return // same venue can't arb against itself
+	}
+	edgeBps := (bestBidPx - bestAskPx) / bestAskPx * 10000
+	if edgeBps < a.cfg.MinEdgeBps {
+		return
+	}
+
+	key := pairKey{symbolID: symbolID, buyEx: bestAskEx, sellEx: bestBidEx}
+	if last, ok := a.lastSignal[key]; ok && time.Since(last) < a.cfg.Cooldown {
+		return // same pair crossing, still within cooldown
+	}
+	a.lastSignal[key] = time.Now()
+
+	a.ring.Emit(shm.Signal{
+		SymbolID: symbolID,
+		BuyEx:    bestAskEx,
+		SellEx:   bestBidEx,
+		EdgeBps:  edgeBps,
+		TsNs:     ts,
+	})
+}